@@ -0,0 +1,56 @@
+package jwks
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheTTL computes the effective cache TTL for a JWKS response. When
+// respectCacheHeaders is enabled, the response's Cache-Control max-age (or,
+// failing that, its Expires header) is honored, but never beyond the
+// configured cacheTimeout. The result is floored at minCacheTimeout so a
+// misbehaving origin can't force sub-second refreshes.
+func (c *Client) cacheTTL(resp *http.Response) time.Duration {
+	ttl := c.config.cacheTimeout * time.Second
+	minTTL := c.config.minCacheTimeout * time.Second
+
+	if c.config.respectCacheHeaders {
+		if maxAge, ok := parseMaxAge(resp.Header.Get("Cache-Control")); ok {
+			if maxAge < ttl {
+				ttl = maxAge
+			}
+		} else if expires := resp.Header.Get("Expires"); expires != "" {
+			if t, err := http.ParseTime(expires); err == nil {
+				if remaining := time.Until(t); remaining < ttl {
+					ttl = remaining
+				}
+			}
+		}
+	}
+
+	if ttl < minTTL {
+		ttl = minTTL
+	}
+	return ttl
+}
+
+// parseMaxAge extracts the "max-age" directive from a Cache-Control header
+// value, returning false if the header is absent or the directive can't be
+// parsed.
+func parseMaxAge(cacheControl string) (time.Duration, bool) {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.TrimSpace(directive)
+		name, value, found := strings.Cut(directive, "=")
+		if !found || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		seconds, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	return 0, false
+}
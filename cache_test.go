@@ -0,0 +1,60 @@
+package jwks
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseMaxAge(t *testing.T) {
+	ttl, ok := parseMaxAge("public, max-age=120")
+	assert(t, ok)
+	assert(t, ttl == 120*time.Second)
+}
+
+func TestParseMaxAgeMissing(t *testing.T) {
+	_, ok := parseMaxAge("public, no-cache")
+	assert(t, !ok)
+}
+
+func TestParseMaxAgeInvalid(t *testing.T) {
+	_, ok := parseMaxAge("max-age=soon")
+	assert(t, !ok)
+}
+
+func TestCacheTTLRespectsMaxAge(t *testing.T) {
+	client := NewClient("http://ilikepie.com", NewConfig())
+	resp := &http.Response{Header: make(http.Header)}
+	resp.Header.Set("Cache-Control", "max-age=30")
+
+	ttl := client.cacheTTL(resp)
+	assert(t, ttl == 30*time.Second)
+}
+
+func TestCacheTTLFloorsAtMinCacheTimeout(t *testing.T) {
+	client := NewClient("http://ilikepie.com", NewConfig())
+	resp := &http.Response{Header: make(http.Header)}
+	resp.Header.Set("Cache-Control", "max-age=0")
+
+	ttl := client.cacheTTL(resp)
+	assert(t, ttl == defaultMinCacheTimeout*time.Second)
+}
+
+func TestCacheTTLCappedAtConfiguredTimeout(t *testing.T) {
+	client := NewClient("http://ilikepie.com", NewConfig())
+	resp := &http.Response{Header: make(http.Header)}
+	resp.Header.Set("Cache-Control", "max-age=999999")
+
+	ttl := client.cacheTTL(resp)
+	assert(t, ttl == defaultcacheTimeout*time.Second)
+}
+
+func TestCacheTTLIgnoredWhenDisabled(t *testing.T) {
+	config := NewConfig().WithRespectCacheHeaders(false)
+	client := NewClient("http://ilikepie.com", config)
+	resp := &http.Response{Header: make(http.Header)}
+	resp.Header.Set("Cache-Control", "max-age=30")
+
+	ttl := client.cacheTTL(resp)
+	assert(t, ttl == defaultcacheTimeout*time.Second)
+}
@@ -3,6 +3,8 @@
 package jwks
 
 import (
+	"context"
+	"crypto"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
@@ -14,36 +16,61 @@ import (
 )
 
 const (
-	defaultRequestTimeout = time.Duration(30)
-	defaultcacheTimeout   = time.Duration(600)
+	defaultRequestTimeout     = time.Duration(30)
+	defaultcacheTimeout       = time.Duration(600)
+	defaultMinCacheTimeout    = time.Duration(5)
+	defaultSoftTTL            = time.Duration(300)
+	defaultMinRefreshInterval = time.Duration(60)
+	defaultCacheGracePeriod   = time.Duration(3600)
 )
 
 // Client reads signing keys from a JSON Web Key set endpoint.
 type Client struct {
-	config      *ClientConfig
-	httpClient  *http.Client
-	endpointURL string
-	expiration  time.Time
-	keys        *Keys
-	mutex       sync.RWMutex
+	config         *ClientConfig
+	httpClient     *http.Client
+	endpointURL    string
+	softExpiration time.Time
+	hardExpiration time.Time
+	keys           *Keys
+	etag           string
+	lastModified   string
+	mutex          sync.RWMutex
+
+	refresh refreshGroup
+
+	unknownKidMu          sync.Mutex
+	lastUnknownKidRefresh time.Time
 }
 
 // ClientConfig contains configuration for JWKS client.
 type ClientConfig struct {
-	disableStrictTLS   bool
-	enableDebugLogging bool
-	logger             *log.Logger
-	cacheTimeout       time.Duration
-	requestTimeout     time.Duration
+	disableStrictTLS    bool
+	enableDebugLogging  bool
+	logger              *log.Logger
+	cacheTimeout        time.Duration
+	requestTimeout      time.Duration
+	respectCacheHeaders bool
+	minCacheTimeout     time.Duration
+	softTTL             time.Duration
+	refreshOnUnknownKid bool
+	minRefreshInterval  time.Duration
+	cache               Cache
+	cacheGracePeriod    time.Duration
 }
 
 // NewConfig creates a new configuration object pre-populated with default values.
 func NewConfig() *ClientConfig {
 	return &ClientConfig{
-		disableStrictTLS: false,
-		cacheTimeout:     defaultcacheTimeout,
-		requestTimeout:   defaultRequestTimeout,
-		logger:           log.New(os.Stderr, "go-jwks: ", log.LstdFlags|log.Lshortfile),
+		disableStrictTLS:    false,
+		cacheTimeout:        defaultcacheTimeout,
+		requestTimeout:      defaultRequestTimeout,
+		respectCacheHeaders: true,
+		minCacheTimeout:     defaultMinCacheTimeout,
+		softTTL:             defaultSoftTTL,
+		refreshOnUnknownKid: true,
+		minRefreshInterval:  defaultMinRefreshInterval,
+		cacheGracePeriod:    defaultCacheGracePeriod,
+		logger:              log.New(os.Stderr, "go-jwks: ", log.LstdFlags|log.Lshortfile),
 	}
 }
 
@@ -75,57 +102,71 @@ func (c *ClientConfig) WithDebugLogging(enableDebugLogging bool, logger *log.Log
 	return c
 }
 
-// Key is a JSON web key returned by the JWKS request.
-type Key struct {
-	// The "kid" (key ID) parameter is used to match a specific key.
-	Kid string `json:"kid"`
-
-	// The "kty" (key type) parameter identifies the cryptographic algorithm
-	// family used with the key, such as "RSA" or "EC".  "kty" values should
-	// either be registered in the IANA "JSON Web Key Types" registry
-	// established by or be a value that contains a Collision-resistant name.
-	// The "kty" value is a case-sensitive string.
-	Kty string `json:"kty"`
-
-	// The "alg" (algorithm) parameter identifies the algorithm intended for
-	// use with the key.  The values used should either be registered in the
-	// IANA "JSON Web Signature and Encryption Algorithms" registry
-	// established by JWA or be a value that contains a Collision-
-	// Resistant Name.  The "alg" value is a case-sensitive ASCII string.
-	Alg string `json:"alg"`
+// WithRespectCacheHeaders enables or disables honoring the JWKS endpoint's
+// Cache-Control/Expires and ETag/Last-Modified headers. It is enabled by
+// default; the effective cache TTL is capped at cacheTimeout and then
+// floored at minCacheTimeout.
+func (c *ClientConfig) WithRespectCacheHeaders(respectCacheHeaders bool) *ClientConfig {
+	c.respectCacheHeaders = respectCacheHeaders
+	return c
+}
 
-	// The "use" (public key use) parameter identifies the intended use of
-	// the public key. The "use" parameter is employed to indicate whether
-	// a public key is used for encrypting data or verifying the signature
-	// on data.
-	Use string `json:"use"`
+// WithSoftTTL sets the soft TTL. Once a cached key set is older than this,
+// it is still served immediately, but a single background refresh is
+// kicked off so the cache doesn't run all the way to the hard TTL. It is
+// capped at the effective hard TTL.
+func (c *ClientConfig) WithSoftTTL(ttl time.Duration) *ClientConfig {
+	c.softTTL = ttl
+	return c
+}
 
-	// The "x5c" (X.509 certificate chain) parameter contains a chain of one
-	// or more PKIX certificates.  The certificate chain is represented as a
-	// JSON array of certificate value strings.  Each string in the array is
-	// a base64-encoded (not base64url-encoded) DER [ITU.X690.1994] PKIX
-	// certificate value.
-	X5c []string `json:"x5c"`
+// WithHardTTL sets the hard cache TTL: once a cached key set is older than
+// this, callers block on a synchronous refresh. It is an alias for
+// WithCacheTimeout.
+func (c *ClientConfig) WithHardTTL(ttl time.Duration) *ClientConfig {
+	return c.WithCacheTimeout(ttl)
+}
 
-	// The "x5t" (X.509 certificate SHA-1 thumbprint) parameter is a
-	// base64url-encoded SHA-1 thumbprint of the DER encoding of an X.509
-	// certificate.
-	X5t string `json:"x5t"`
+// WithRefreshOnUnknownKid enables or disables triggering an out-of-band
+// refresh when GetSigningKey is asked for a kid that isn't present in the
+// cached key set, so a newly rotated key is picked up right away instead
+// of at the next scheduled refresh. Refreshes triggered this way are
+// rate-limited by WithMinRefreshInterval. Enabled by default.
+func (c *ClientConfig) WithRefreshOnUnknownKid(refreshOnUnknownKid bool) *ClientConfig {
+	c.refreshOnUnknownKid = refreshOnUnknownKid
+	return c
+}
 
-	// N is the RSA key value modulus.
-	N string `json:"n"`
+// WithMinRefreshInterval sets the minimum time between out-of-band
+// refreshes triggered by an unknown kid.
+func (c *ClientConfig) WithMinRefreshInterval(interval time.Duration) *ClientConfig {
+	c.minRefreshInterval = interval
+	return c
+}
 
-	// E is the RSA key value public exponent.
-	E string `json:"e"`
+// WithCache configures a persistent Cache (such as a FileCache) that the
+// client loads from on startup and writes to after every successful fetch,
+// so a process restart doesn't have to hit the network before it can verify
+// its first token. If this config is used with a MultiClient, cache must
+// implement NamespacedCache so each issuer's sub-Client gets its own
+// storage; otherwise MultiClient disables the cache rather than let
+// issuers clobber each other's persisted key set.
+func (c *ClientConfig) WithCache(cache Cache) *ClientConfig {
+	c.cache = cache
+	return c
 }
 
-// Keys represents a set of JSON web keys.
-type Keys struct {
-	// Keys is an array of JSON web keys.
-	Keys []Key `json:"keys"`
+// WithCacheGracePeriod sets how long past its nominal hard TTL a key set
+// loaded from the persistent Cache may still be served if the JWKS
+// endpoint is unreachable. Only takes effect when a Cache is configured.
+func (c *ClientConfig) WithCacheGracePeriod(grace time.Duration) *ClientConfig {
+	c.cacheGracePeriod = grace
+	return c
 }
 
-// NewClient creates a new JWKS client. The client is thread-safe.
+// NewClient creates a new JWKS client. The client is thread-safe. If the
+// config has a Cache configured, the client loads the persisted key set
+// immediately so the first call doesn't have to wait on a network fetch.
 func NewClient(jwksEndpoint string, config *ClientConfig) *Client {
 	if config == nil {
 		config = NewConfig()
@@ -140,54 +181,149 @@ func NewClient(jwksEndpoint string, config *ClientConfig) *Client {
 			},
 		},
 	}
+
+	if config.cache != nil {
+		if keys, expiration, err := config.cache.Get(context.Background()); err == nil && keys != nil {
+			client.keys = keys
+			client.hardExpiration = expiration
+			client.softExpiration = expiration
+		} else if err != nil && config.enableDebugLogging {
+			config.logger.Println(err)
+		}
+	}
+
 	return client
 }
 
 // GetKeys retrieves the keys from the JWKS endpoint. Cached values will be returned
 // if available.
 func (c *Client) GetKeys() (keys []Key, err error) {
+	return c.GetKeysContext(context.Background())
+}
+
+// GetKeysContext is the context-aware equivalent of GetKeys. The supplied
+// context bounds any network request made to refresh the key set, allowing
+// callers to propagate cancellation or a deadline from an inbound request.
+//
+// Once the cached key set passes its soft TTL, GetKeysContext still returns
+// the stale set immediately while a single background goroutine refreshes
+// it; concurrent refreshes (background or caller-triggered) are coalesced
+// so only one fetch hits the network at a time. Once it passes the hard
+// TTL, callers block on a synchronous refresh instead.
+func (c *Client) GetKeysContext(ctx context.Context) (keys []Key, err error) {
 	// Oh this is all so ugly. There must be a better way :(
 	defer func() {
 		if rerr := recover(); rerr != nil && c.config.enableDebugLogging {
 			c.config.logger.Printf("Recovered from panic [%s].", rerr)
 		}
 	}()
+
 	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+	now := time.Now()
+	hardExpired := c.keys == nil || now.After(c.hardExpiration)
+	softExpired := c.keys == nil || now.After(c.softExpiration)
+	c.mutex.RUnlock()
 
-	if c.keys == nil || time.Now().After(c.expiration) {
-		c.mutex.RUnlock()
-		if err = c.updateKeys(); err == nil {
-			keys, err = c.keys.Keys, nil
-		} else if c.config.enableDebugLogging {
-			c.config.logger.Println(err)
+	if hardExpired {
+		if err = c.refresh.do(func() error { return c.updateKeys(ctx, false) }); err != nil {
+			if c.config.enableDebugLogging {
+				c.config.logger.Println(err)
+			}
+			if c.withinCacheGrace() {
+				err = nil
+			}
 		}
-		c.mutex.RLock()
+	} else if softExpired {
+		c.refresh.spawn(func() error { return c.updateKeys(context.Background(), true) })
 	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
 	return c.keys.Keys, err
 }
 
 // GetSigningKey is a convenience function which returns a signing key with
 // the specified key ID, or nil if the key doesn't exist in the key set.
 func (c *Client) GetSigningKey(kid string) (result *Key, err error) {
-	keys, err := c.GetKeys()
-	if err == nil {
-		for _, key := range keys {
-			if key.Kid == kid && key.Use == "sig" {
-				result = &key
+	return c.GetSigningKeyContext(context.Background(), kid)
+}
+
+// GetSigningKeyContext is the context-aware equivalent of GetSigningKey. If
+// the kid isn't found in the cached key set, and WithRefreshOnUnknownKid
+// hasn't been disabled, it triggers an out-of-band refresh (rate-limited by
+// WithMinRefreshInterval) and looks again before giving up, so a key
+// rotated on the issuer's side is picked up without waiting for the next
+// scheduled refresh.
+func (c *Client) GetSigningKeyContext(ctx context.Context, kid string) (result *Key, err error) {
+	keys, err := c.GetKeysContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result = findSigningKey(keys, kid)
+	if result == nil && c.config.refreshOnUnknownKid && c.shouldRefreshForUnknownKid() {
+		if rerr := c.refresh.do(func() error { return c.updateKeys(ctx, true) }); rerr != nil {
+			if c.config.enableDebugLogging {
+				c.config.logger.Println(rerr)
 			}
+		} else {
+			c.mutex.RLock()
+			result = findSigningKey(c.keys.Keys, kid)
+			c.mutex.RUnlock()
 		}
 	}
-	return result, err
+	return result, nil
 }
 
-func (c *Client) updateKeys() error {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+func findSigningKey(keys []Key, kid string) *Key {
+	for _, key := range keys {
+		if key.Kid == kid && key.Use == "sig" {
+			return &key
+		}
+	}
+	return nil
+}
 
-	// Another writer may have updated while we were waiting for the
-	// write lock, so check again.
-	if time.Now().Before(c.expiration) {
+// GetSigningKeyParsed is a convenience function which returns the parsed
+// crypto.PublicKey for the signing key with the specified key ID, ready to
+// use for signature verification.
+func (c *Client) GetSigningKeyParsed(kid string) (crypto.PublicKey, error) {
+	return c.GetSigningKeyParsedContext(context.Background(), kid)
+}
+
+// GetSigningKeyParsedContext is the context-aware equivalent of
+// GetSigningKeyParsed.
+func (c *Client) GetSigningKeyParsedContext(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	key, err := c.GetSigningKeyContext(ctx, kid)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("jwks: no signing key found for kid %q", kid)
+	}
+	return key.PublicKey()
+}
+
+// updateKeys fetches the key set from the JWKS endpoint. Unless force is
+// set, it's a no-op if the cached key set hasn't reached its hard TTL yet,
+// which guards against a thundering herd of callers that all observed an
+// expired cache before any of them acquired the refresh.
+//
+// The fetch and JSON decode happen without holding c.mutex: only the
+// validators read before the request and the final swap of c.keys/
+// expirations/etag take the lock, and each holds it only briefly. Holding
+// the write lock across the HTTP round-trip would park every reader behind
+// it (Go's RWMutex favors pending writers), turning a background
+// soft-TTL refresh into the same stall-every-caller behavior this cache
+// design exists to avoid.
+func (c *Client) updateKeys(ctx context.Context, force bool) error {
+	c.mutex.RLock()
+	hardExpiration := c.hardExpiration
+	etag := c.etag
+	lastModified := c.lastModified
+	c.mutex.RUnlock()
+
+	if !force && time.Now().Before(hardExpiration) {
 		return nil
 	}
 
@@ -195,7 +331,20 @@ func (c *Client) updateKeys() error {
 		c.config.logger.Println("Begin fetch key set.")
 	}
 
-	resp, err := c.httpClient.Get(c.endpointURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpointURL, nil)
+	if err != nil {
+		return err
+	}
+	if c.config.respectCacheHeaders {
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -203,6 +352,28 @@ func (c *Client) updateKeys() error {
 	// Always close any non-nil response Body
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		c.mutex.Lock()
+		c.setExpiration(resp)
+		// RFC 7232 permits a server to send a new ETag/Last-Modified on a
+		// 304, so refresh the validators we'll send next time rather than
+		// keeping the ones that produced this response.
+		if c.config.respectCacheHeaders {
+			if v := resp.Header.Get("ETag"); v != "" {
+				c.etag = v
+			}
+			if v := resp.Header.Get("Last-Modified"); v != "" {
+				c.lastModified = v
+			}
+		}
+		hardExpiration = c.hardExpiration
+		c.mutex.Unlock()
+		if c.config.enableDebugLogging {
+			c.config.logger.Printf("Keys not modified. Expires: %v.\n", hardExpiration)
+		}
+		return nil
+	}
+
 	if resp.StatusCode >= 400 {
 		return fmt.Errorf("Keys request returned non-success status (%d)", resp.StatusCode)
 	}
@@ -212,10 +383,23 @@ func (c *Client) updateKeys() error {
 		return err
 	}
 
+	c.mutex.Lock()
 	c.keys = keys
-	c.expiration = time.Now().Add(c.config.cacheTimeout * time.Second)
+	c.setExpiration(resp)
+	if c.config.respectCacheHeaders {
+		c.etag = resp.Header.Get("ETag")
+		c.lastModified = resp.Header.Get("Last-Modified")
+	}
+	hardExpiration = c.hardExpiration
+	c.mutex.Unlock()
+
+	if c.config.cache != nil {
+		if perr := c.config.cache.Put(ctx, keys, hardExpiration); perr != nil && c.config.enableDebugLogging {
+			c.config.logger.Println(perr)
+		}
+	}
 	if c.config.enableDebugLogging {
-		c.config.logger.Printf("Fetched %d keys. Expires: %v.\n", len(c.keys.Keys), c.expiration)
+		c.config.logger.Printf("Fetched %d keys. Expires: %v.\n", len(keys.Keys), hardExpiration)
 	}
 	return nil
 }
@@ -2,6 +2,7 @@ package jwks
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"io/ioutil"
 	"log"
@@ -126,6 +127,51 @@ func (t *mockMalformedTransport) RoundTrip(req *http.Request) (*http.Response, e
 	return response, nil
 }
 
+type mockConditionalTransport struct {
+	requests []*http.Request
+}
+
+func (t *mockConditionalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests = append(t.requests, req)
+	response := &http.Response{
+		Header:  make(http.Header),
+		Request: req,
+	}
+
+	if req.Header.Get("If-None-Match") == `"v1"` {
+		response.StatusCode = http.StatusNotModified
+		response.Body = ioutil.NopCloser(strings.NewReader(""))
+		return response, nil
+	}
+
+	response.StatusCode = http.StatusOK
+	response.Header.Set("Cache-Control", "max-age=1")
+	response.Header.Set("ETag", `"v1"`)
+	responseBody := `{"keys":[{"alg":"RS256","kty":"RSA","use":"sig","n":"VKOoRQ","e":"AQAB","kid":"GREY2MQ"}]}`
+	response.Body = ioutil.NopCloser(strings.NewReader(responseBody))
+	return response, nil
+}
+
+func TestConditionalRequestExtendsCacheOn304(t *testing.T) {
+	transport := &mockConditionalTransport{}
+	config := NewConfig()
+	config.minCacheTimeout = 0
+	client := NewClient("http://ilikepie.com", config)
+	client.httpClient = &http.Client{Transport: transport}
+
+	keys, err := client.GetKeys()
+	assert(t, err == nil)
+	assert(t, len(keys) == 1)
+
+	time.Sleep(1100 * time.Millisecond)
+
+	keys, err = client.GetKeys()
+	assert(t, err == nil)
+	assert(t, len(keys) == 1)
+	assert(t, len(transport.requests) == 2)
+	assert(t, transport.requests[1].Header.Get("If-None-Match") == `"v1"`)
+}
+
 func setupMockedHTTPTest(resultType string) *Client {
 	client := http.DefaultClient
 	switch true {
@@ -181,7 +227,7 @@ func TestSuccessHttpRequestDebugLogging(t *testing.T) {
 
 func TestSuccessHttpRequestNoKey(t *testing.T) {
 	client := setupMockedHTTPTest("success")
-	assert(t, client.expiration.IsZero())
+	assert(t, client.hardExpiration.IsZero())
 	keys, err := client.GetKeys()
 
 	assert(t, err == nil)
@@ -197,7 +243,7 @@ func TestSuccessHttpRequestNoKey(t *testing.T) {
 	assert(t, key.X5c[0] == "D4dtuk")
 	assert(t, key.E == "AQAB")
 	assert(t, key.N == "VKOoRQ")
-	assert(t, !client.expiration.IsZero())
+	assert(t, !client.hardExpiration.IsZero())
 }
 
 func TestMalformedHttpRequest(t *testing.T) {
@@ -221,11 +267,59 @@ func TestGetSigningKeyForNonExistingKey(t *testing.T) {
 	assert(t, key == nil)
 }
 
+func TestGetKeysContextReturnsKeys(t *testing.T) {
+	client := setupMockedHTTPTest("success")
+	keys, err := client.GetKeysContext(context.Background())
+	assert(t, err == nil)
+	assert(t, len(keys) == 1)
+}
+
+func TestGetSigningKeyContextReturnsKey(t *testing.T) {
+	client := setupMockedHTTPTest("success")
+	key, err := client.GetSigningKeyContext(context.Background(), "GREY2MQ")
+	assert(t, err == nil)
+	assert(t, key != nil)
+}
+
 func TestExpirationCheckBeforeUpdate(t *testing.T) {
 	client := NewClient("endpoint", nil)
-	client.expiration = time.Now().AddDate(1, 0, 0)
-	err := client.updateKeys()
+	client.hardExpiration = time.Now().AddDate(1, 0, 0)
+	err := client.updateKeys(context.Background(), false)
+	assert(t, err == nil)
+}
+
+func TestUpdateKeysForceIgnoresExpiration(t *testing.T) {
+	client := setupMockedHTTPTest("success")
+	client.hardExpiration = time.Now().AddDate(1, 0, 0)
+	err := client.updateKeys(context.Background(), true)
+	assert(t, err == nil)
+	assert(t, client.keys != nil)
+}
+
+func TestSoftExpiredServesStaleWhileRefreshing(t *testing.T) {
+	config := NewConfig().WithSoftTTL(time.Duration(0)).WithCacheTimeout(time.Duration(60))
+	client := NewClient("http://ilikepie.com", config)
+	client.httpClient = &http.Client{Transport: &mockSuccessTransport{}}
+
+	keys, err := client.GetKeys()
+	assert(t, err == nil)
+	assert(t, len(keys) == 1)
+
+	// The soft TTL has already elapsed, so this call should still return
+	// the cached keys immediately while a refresh happens in the background.
+	keys, err = client.GetKeys()
 	assert(t, err == nil)
+	assert(t, len(keys) == 1)
+
+	// Let the background refresh settle before other tests mutate shared
+	// transport state.
+	time.Sleep(50 * time.Millisecond)
+}
+
+func TestRefreshOnUnknownKidIsRateLimited(t *testing.T) {
+	client := setupMockedHTTPTest("success")
+	assert(t, client.shouldRefreshForUnknownKid())
+	assert(t, !client.shouldRefreshForUnknownKid())
 }
 
 func assert(t *testing.T, condition bool) {
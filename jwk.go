@@ -0,0 +1,245 @@
+package jwks
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Key is a JSON web key returned by the JWKS request.
+type Key struct {
+	// The "kid" (key ID) parameter is used to match a specific key.
+	Kid string `json:"kid"`
+
+	// The "kty" (key type) parameter identifies the cryptographic algorithm
+	// family used with the key, such as "RSA" or "EC".  "kty" values should
+	// either be registered in the IANA "JSON Web Key Types" registry
+	// established by or be a value that contains a Collision-resistant name.
+	// The "kty" value is a case-sensitive string.
+	Kty string `json:"kty"`
+
+	// The "alg" (algorithm) parameter identifies the algorithm intended for
+	// use with the key.  The values used should either be registered in the
+	// IANA "JSON Web Signature and Encryption Algorithms" registry
+	// established by JWA or be a value that contains a Collision-
+	// Resistant Name.  The "alg" value is a case-sensitive ASCII string.
+	Alg string `json:"alg"`
+
+	// The "use" (public key use) parameter identifies the intended use of
+	// the public key. The "use" parameter is employed to indicate whether
+	// a public key is used for encrypting data or verifying the signature
+	// on data.
+	Use string `json:"use"`
+
+	// The "x5c" (X.509 certificate chain) parameter contains a chain of one
+	// or more PKIX certificates.  The certificate chain is represented as a
+	// JSON array of certificate value strings.  Each string in the array is
+	// a base64-encoded (not base64url-encoded) DER [ITU.X690.1994] PKIX
+	// certificate value.
+	X5c []string `json:"x5c"`
+
+	// The "x5t" (X.509 certificate SHA-1 thumbprint) parameter is a
+	// base64url-encoded SHA-1 thumbprint of the DER encoding of an X.509
+	// certificate.
+	X5t string `json:"x5t"`
+
+	// The "x5t#S256" (X.509 certificate SHA-256 thumbprint) parameter is a
+	// base64url-encoded SHA-256 thumbprint of the DER encoding of an X.509
+	// certificate.
+	X5tS256 string `json:"x5t#S256"`
+
+	// N is the RSA key value modulus.
+	N string `json:"n"`
+
+	// E is the RSA key value public exponent.
+	E string `json:"e"`
+
+	// Crv is the "crv" (curve) parameter, used by EC keys ("P-256",
+	// "P-384", "P-521") and OKP keys ("Ed25519").
+	Crv string `json:"crv"`
+
+	// X is the x coordinate for EC keys, or the public key value for OKP
+	// keys, base64url-encoded.
+	X string `json:"x"`
+
+	// Y is the y coordinate for EC keys, base64url-encoded.
+	Y string `json:"y"`
+
+	// K is the symmetric key value for "oct" keys, base64url-encoded.
+	K string `json:"k"`
+}
+
+// Keys represents a set of JSON web keys.
+type Keys struct {
+	// Keys is an array of JSON web keys.
+	Keys []Key `json:"keys"`
+}
+
+// PublicKey derives the crypto.PublicKey represented by this JWK. RSA keys
+// ("RSA") produce an *rsa.PublicKey, EC keys ("EC") produce an
+// *ecdsa.PublicKey on the P-256, P-384, or P-521 curve, and OKP keys
+// ("OKP") with curve "Ed25519" produce an ed25519.PublicKey. If the key
+// carries an "x5c" certificate chain, the leaf certificate is parsed and
+// its public key is verified to match the JWK-derived parameters, then
+// returned in preference to the raw JWK fields.
+func (k *Key) PublicKey() (crypto.PublicKey, error) {
+	var (
+		key crypto.PublicKey
+		err error
+	)
+
+	switch k.Kty {
+	case "RSA":
+		key, err = k.rsaPublicKey()
+	case "EC":
+		key, err = k.ecPublicKey()
+	case "OKP":
+		key, err = k.okpPublicKey()
+	default:
+		return nil, fmt.Errorf("jwks: unsupported key type %q for kid %q", k.Kty, k.Kid)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(k.X5c) > 0 {
+		certKey, err := k.x5cPublicKey()
+		if err != nil {
+			return nil, err
+		}
+		if !publicKeysEqual(key, certKey) {
+			return nil, fmt.Errorf("jwks: x5c certificate key does not match JWK parameters for kid %q", k.Kid)
+		}
+		return certKey, nil
+	}
+
+	return key, nil
+}
+
+func (k *Key) rsaPublicKey() (*rsa.PublicKey, error) {
+	if k.N == "" || k.E == "" {
+		return nil, fmt.Errorf("jwks: RSA key %q missing n or e", k.Kid)
+	}
+
+	nBytes, err := decodeSegment(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: decoding n for kid %q: %w", k.Kid, err)
+	}
+
+	eBytes, err := decodeSegment(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: decoding e for kid %q: %w", k.Kid, err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
+
+func (k *Key) ecPublicKey() (*ecdsa.PublicKey, error) {
+	curve, err := ecCurve(k.Crv)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: EC key %q: %w", k.Kid, err)
+	}
+
+	if k.X == "" || k.Y == "" {
+		return nil, fmt.Errorf("jwks: EC key %q missing x or y", k.Kid)
+	}
+
+	xBytes, err := decodeSegment(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: decoding x for kid %q: %w", k.Kid, err)
+	}
+
+	yBytes, err := decodeSegment(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: decoding y for kid %q: %w", k.Kid, err)
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(xBytes),
+		Y:     new(big.Int).SetBytes(yBytes),
+	}, nil
+}
+
+func ecCurve(crv string) (elliptic.Curve, error) {
+	switch crv {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	case "P-521":
+		return elliptic.P521(), nil
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", crv)
+	}
+}
+
+func (k *Key) okpPublicKey() (ed25519.PublicKey, error) {
+	if k.Crv != "Ed25519" {
+		return nil, fmt.Errorf("jwks: OKP key %q has unsupported curve %q", k.Kid, k.Crv)
+	}
+
+	if k.X == "" {
+		return nil, fmt.Errorf("jwks: OKP key %q missing x", k.Kid)
+	}
+
+	xBytes, err := decodeSegment(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: decoding x for kid %q: %w", k.Kid, err)
+	}
+
+	if len(xBytes) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("jwks: OKP key %q has invalid Ed25519 public key length %d", k.Kid, len(xBytes))
+	}
+
+	return ed25519.PublicKey(xBytes), nil
+}
+
+func (k *Key) x5cPublicKey() (crypto.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(k.X5c[0])
+	if err != nil {
+		return nil, fmt.Errorf("jwks: decoding x5c for kid %q: %w", k.Kid, err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: parsing x5c certificate for kid %q: %w", k.Kid, err)
+	}
+
+	return cert.PublicKey, nil
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	switch a := a.(type) {
+	case *rsa.PublicKey:
+		b, ok := b.(*rsa.PublicKey)
+		return ok && a.E == b.E && a.N.Cmp(b.N) == 0
+	case *ecdsa.PublicKey:
+		b, ok := b.(*ecdsa.PublicKey)
+		return ok && a.Curve == b.Curve && a.X.Cmp(b.X) == 0 && a.Y.Cmp(b.Y) == 0
+	case ed25519.PublicKey:
+		b, ok := b.(ed25519.PublicKey)
+		return ok && a.Equal(b)
+	default:
+		return false
+	}
+}
+
+// decodeSegment base64url-decodes a JWK member value, normalizing the
+// padding since issuers are inconsistent about including it.
+func decodeSegment(seg string) ([]byte, error) {
+	if l := len(seg) % 4; l > 0 {
+		seg += strings.Repeat("=", 4-l)
+	}
+	return base64.URLEncoding.DecodeString(seg)
+}
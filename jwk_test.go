@@ -0,0 +1,95 @@
+package jwks
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"testing"
+)
+
+const (
+	testRSAN = "zo7FlzlOc3RtJGqIkxTE2UoXkP3Lmu0dufY1s1ZovJcIl9uY3Bz89eV2tGrsvy9rfG0WFwJYRsQaeTW0qg46xEQ-zNCNE6YwnvCU-WilIeueqGhxsPn7_Wiqr3Qx8nC3mm5fB-J7W2-NRyST_6NvMDCAqt93ZlmceGq7kc6AHd92_na4u8Snkw74tUg3G0pHRyEGo5M1a7-2Wm2Vf7iZDKYfQ5dwBr8Djrv8xKY56NNVWDxJvYOJl6maD5TsbRmd5tUqMwhBHf8NrLt4XeUX3safyucEYzInjcu6iK25lp-XQ1D0NJEkAle2S8XtprrDotHDS-yhX8pzPMfB-vFxxw"
+	testRSAE = "AQAB"
+
+	// testRSANMismatch is a different RSA modulus, used to assert that an
+	// x5c certificate is rejected when it doesn't match the JWK parameters.
+	testRSANMismatch = "3hfd007CGaqzSN8ev2SFOAyoJ_Q9wZTDx48XvKwC8gXfVEYt857cYgf2ino9Hu0XT_OyK4vUtF55Kk7J9Kq30-v5rAQ4dyjmZTr5vDZwJ2kEiZfq-qgBM0f3J2_lrYrLFSeUVN2PXli0fJd4VgUcnbx5bhhyHGtxdEbK10uJj7qfIcUaMidJ6gQeiKGFm_392ZH9IkkpBZ_sQcqGTZ-YkEfUJojEQjOfH0Ec9oUJPbUIPPoWTw24lJu2k3ZLIWcdqpHPpBpeBtTsa0mINuCa6-QlR306tOO953sIM9t0ZR5BvtP1-9c8PYHcjt23HUqWahTKEmF6JwPqXgm83Ohsbw"
+
+	// testRSACert is a self-signed certificate whose public key matches
+	// testRSAN/testRSAE.
+	testRSACert = "MIIClzCCAX+gAwIBAgIBATANBgkqhkiG9w0BAQsFADAPMQ0wCwYDVQQDEwR0ZXN0MB4XDTI2MDcyNzAyNDMwNFoXDTI2MDcyNzAzNDMwNFowDzENMAsGA1UEAxMEdGVzdDCCASIwDQYJKoZIhvcNAQEBBQADggEPADCCAQoCggEBAM6OxZc5TnN0bSRqiJMUxNlKF5D9y5rtHbn2NbNWaLyXCJfbmNwc/PXldrRq7L8va3xtFhcCWEbEGnk1tKoOOsREPszQjROmMJ7wlPlopSHrnqhocbD5+/1oqq90MfJwt5puXwfie1tvjUckk/+jbzAwgKrfd2ZZnHhqu5HOgB3fdv52uLvEp5MO+LVINxtKR0chBqOTNWu/tlptlX+4mQymH0OXcAa/A467/MSmOejTVVg8Sb2DiZepmg+U7G0ZnebVKjMIQR3/Day7eF3lF97Gn8rnBGMyJ43LuoituZafl0NQ9DSRJAJXtkvF7aa6w6LRw0vsoV/KczzHwfrxcccCAwEAATANBgkqhkiG9w0BAQsFAAOCAQEAeeKwFze5CeNTfR26tf9Rw0sE2Bu3sgTajsnwf9i5a0/1CBl30P9xfsn8+0a5H2QMncNrDXLt91IjviNqK8WaC33JB+vt2utfbhuvByHNVV0MHN4vCsL4zxO1lecjIlqejImo9DdXPePBXegMY91HCBAMLTD8e5/3uXcrZkL/mbrSrehQtlIhoz722PQg1lhOfvXenNk8fFlX6M8idWsI8h15D68umbay4UZAcNOjqUSpjyIc+wSk71cne9tdC4Dp3gIWsvCyHWF0Ii3hP+x3rC2NEH/eOkvOE3yAV6QdCBiBzZzJq/bWVrsmovVnqyri5iYx8l/ajdtZ/3z9O202Vg=="
+
+	testECX = "rh-ePMbjrW8OCw_NSdCuRUfg-BelWQeHvtkeLQDJo2Y"
+	testECY = "yv30T5aq2aQJ281sqp2Kyv8UXAUw0y8FIgzHPTfzsJw"
+
+	testOKPX = "jAZzebT0b6A9Q9PFyhF9ZhnhvJOFx-mjg9tnJUvVi1o"
+)
+
+func TestKeyPublicKeyRSA(t *testing.T) {
+	key := &Key{Kty: "RSA", N: testRSAN, E: testRSAE}
+	pub, err := key.PublicKey()
+	assert(t, err == nil)
+
+	rsaKey, ok := pub.(*rsa.PublicKey)
+	assert(t, ok)
+	assert(t, rsaKey.E == 65537)
+}
+
+func TestKeyPublicKeyRSAMissingFields(t *testing.T) {
+	key := &Key{Kty: "RSA"}
+	_, err := key.PublicKey()
+	assert(t, err != nil)
+}
+
+func TestKeyPublicKeyEC(t *testing.T) {
+	key := &Key{Kty: "EC", Crv: "P-256", X: testECX, Y: testECY}
+	pub, err := key.PublicKey()
+	assert(t, err == nil)
+
+	ecKey, ok := pub.(*ecdsa.PublicKey)
+	assert(t, ok)
+	assert(t, ecKey.Curve == elliptic.P256())
+}
+
+func TestKeyPublicKeyECUnsupportedCurve(t *testing.T) {
+	key := &Key{Kty: "EC", Crv: "P-NOPE", X: testECX, Y: testECY}
+	_, err := key.PublicKey()
+	assert(t, err != nil)
+}
+
+func TestKeyPublicKeyOKP(t *testing.T) {
+	key := &Key{Kty: "OKP", Crv: "Ed25519", X: testOKPX}
+	pub, err := key.PublicKey()
+	assert(t, err == nil)
+
+	edKey, ok := pub.(ed25519.PublicKey)
+	assert(t, ok)
+	assert(t, len(edKey) == ed25519.PublicKeySize)
+}
+
+func TestKeyPublicKeyOKPUnsupportedCurve(t *testing.T) {
+	key := &Key{Kty: "OKP", Crv: "X25519", X: testOKPX}
+	_, err := key.PublicKey()
+	assert(t, err != nil)
+}
+
+func TestKeyPublicKeyUnsupportedKty(t *testing.T) {
+	key := &Key{Kty: "oct", K: "abc"}
+	_, err := key.PublicKey()
+	assert(t, err != nil)
+}
+
+func TestKeyPublicKeyX5cMatches(t *testing.T) {
+	key := &Key{Kty: "RSA", N: testRSAN, E: testRSAE, X5c: []string{testRSACert}}
+	pub, err := key.PublicKey()
+	assert(t, err == nil)
+	_, ok := pub.(*rsa.PublicKey)
+	assert(t, ok)
+}
+
+func TestKeyPublicKeyX5cMismatch(t *testing.T) {
+	key := &Key{Kty: "RSA", N: testRSANMismatch, E: testRSAE, X5c: []string{testRSACert}}
+	_, err := key.PublicKey()
+	assert(t, err != nil)
+}
@@ -0,0 +1,263 @@
+package jwks
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultMaxDiscoveredIssuers = 128
+
+// IssuerResolver maps an issuer URL to the JWKS endpoint it should use. ctx
+// bounds the resolution itself, so a caller's cancellation or deadline
+// reaches any network request the resolver makes (e.g. OIDC discovery).
+type IssuerResolver func(ctx context.Context, iss string) (jwksURL string, err error)
+
+// MultiClient verifies tokens from any number of issuers. Each issuer's
+// JWKS endpoint is discovered (via OIDC metadata, or a custom
+// IssuerResolver) on first use and cached behind its own Client.
+type MultiClient struct {
+	resolver   IssuerResolver
+	allowlist  map[string]bool
+	config     *ClientConfig
+	maxIssuers int
+
+	mu      sync.Mutex
+	clients map[string]*Client
+	order   []string // least-recently-used issuer first
+}
+
+// NewMultiClient creates a MultiClient that only accepts tokens from the
+// given issuers, discovering each one's JWKS endpoint from its OIDC
+// discovery document at <issuer>/.well-known/openid-configuration.
+func NewMultiClient(issuers []string, config *ClientConfig) *MultiClient {
+	allowlist := make(map[string]bool, len(issuers))
+	for _, iss := range issuers {
+		allowlist[iss] = true
+	}
+	m := newMultiClient(nil, allowlist, config)
+	m.resolver = m.discoverJWKSURI
+	return m
+}
+
+// NewMultiClientWithResolver creates a MultiClient that resolves each
+// issuer's JWKS endpoint with resolver instead of OIDC discovery, useful
+// when issuers aren't known upfront or don't publish discovery metadata.
+// Since any issuer resolver accepts is allowed, discovered clients are
+// bounded by an LRU so an endless stream of unknown issuers can't grow the
+// client set without limit.
+func NewMultiClientWithResolver(resolver IssuerResolver, config *ClientConfig) *MultiClient {
+	return newMultiClient(resolver, nil, config)
+}
+
+func newMultiClient(resolver IssuerResolver, allowlist map[string]bool, config *ClientConfig) *MultiClient {
+	if config == nil {
+		config = NewConfig()
+	}
+	return &MultiClient{
+		resolver:   resolver,
+		allowlist:  allowlist,
+		config:     config,
+		maxIssuers: defaultMaxDiscoveredIssuers,
+		clients:    make(map[string]*Client),
+	}
+}
+
+// GetSigningKey returns the signing key with the specified key ID from the
+// given issuer's key set, discovering and caching that issuer's Client if
+// this is the first time it's been seen.
+func (m *MultiClient) GetSigningKey(ctx context.Context, iss, kid string) (*Key, error) {
+	client, err := m.clientFor(ctx, iss)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetSigningKeyContext(ctx, kid)
+}
+
+// VerifyJWT parses (without verifying) tokenString's header and payload to
+// extract "kid" and "iss", resolves the matching issuer's signing key, and
+// returns its parsed crypto.PublicKey. It does not itself check the
+// signature, expiration, or any other claim; callers should pass the
+// returned key to their JWT library to perform the actual verification.
+func (m *MultiClient) VerifyJWT(ctx context.Context, tokenString string) (crypto.PublicKey, error) {
+	header, payload, err := parseUnverifiedJWT(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if header.Kid == "" {
+		return nil, fmt.Errorf("jwks: token header is missing kid")
+	}
+	if payload.Iss == "" {
+		return nil, fmt.Errorf("jwks: token payload is missing iss")
+	}
+
+	key, err := m.GetSigningKey(ctx, payload.Iss, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("jwks: no signing key found for kid %q from issuer %q", header.Kid, payload.Iss)
+	}
+	return key.PublicKey()
+}
+
+// clientFor returns the cached Client for iss, discovering and creating it
+// if this is the first time it's been seen. Unknown issuers are rejected
+// when an allowlist is configured.
+func (m *MultiClient) clientFor(ctx context.Context, iss string) (*Client, error) {
+	m.mu.Lock()
+	if client, ok := m.clients[iss]; ok {
+		m.touch(iss)
+		m.mu.Unlock()
+		return client, nil
+	}
+	m.mu.Unlock()
+
+	if m.allowlist != nil && !m.allowlist[iss] {
+		return nil, fmt.Errorf("jwks: issuer %q is not allowlisted", iss)
+	}
+
+	jwksURL, err := m.resolver(ctx, iss)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: discovering JWKS endpoint for issuer %q: %w", iss, err)
+	}
+	client := NewClient(jwksURL, m.configFor(iss))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.clients[iss]; ok {
+		// Another goroutine discovered this issuer first.
+		m.touch(iss)
+		return existing, nil
+	}
+	m.clients[iss] = client
+	m.order = append(m.order, iss)
+	m.evict()
+	return client, nil
+}
+
+// configFor returns the ClientConfig to use for issuer iss's sub-Client. If
+// m.config has a Cache configured, every issuer sharing it verbatim would
+// mean every sub-Client loads and overwrites the same persisted key set on
+// startup and refresh. So a NamespacedCache is scoped to iss, and any other
+// Cache is dropped (with a debug log) rather than risk that corruption.
+func (m *MultiClient) configFor(iss string) *ClientConfig {
+	if m.config.cache == nil {
+		return m.config
+	}
+
+	config := *m.config
+	if nc, ok := m.config.cache.(NamespacedCache); ok {
+		config.cache = nc.ForNamespace(iss)
+	} else {
+		config.cache = nil
+		if m.config.enableDebugLogging {
+			m.config.logger.Printf("jwks: Cache %T does not implement NamespacedCache; disabling persistent cache for issuer %q to avoid cross-issuer corruption", m.config.cache, iss)
+		}
+	}
+	return &config
+}
+
+// touch marks iss as most recently used. Must be called with mu held.
+func (m *MultiClient) touch(iss string) {
+	for i, v := range m.order {
+		if v == iss {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+	m.order = append(m.order, iss)
+}
+
+// evict drops the least-recently-used issuers until the client set is back
+// within maxIssuers. Must be called with mu held.
+func (m *MultiClient) evict() {
+	for len(m.order) > m.maxIssuers {
+		oldest := m.order[0]
+		m.order = m.order[1:]
+		delete(m.clients, oldest)
+	}
+}
+
+// discoverJWKSURI is the default IssuerResolver: it fetches the issuer's
+// OIDC discovery document and reads its "jwks_uri", honoring the
+// MultiClient's configured request timeout and TLS policy and the caller's
+// ctx for cancellation/deadline propagation.
+func (m *MultiClient) discoverJWKSURI(ctx context.Context, iss string) (string, error) {
+	discoveryURL := strings.TrimRight(iss, "/") + "/.well-known/openid-configuration"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	httpClient := &http.Client{
+		Timeout: m.config.requestTimeout * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: m.config.disableStrictTLS},
+		},
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("jwks: OIDC discovery for %q returned non-success status (%d)", iss, resp.StatusCode)
+	}
+
+	var metadata struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return "", err
+	}
+	if metadata.JWKSURI == "" {
+		return "", fmt.Errorf("jwks: OIDC discovery for %q did not return a jwks_uri", iss)
+	}
+	return metadata.JWKSURI, nil
+}
+
+type jwtHeader struct {
+	Kid string `json:"kid"`
+}
+
+type jwtPayload struct {
+	Iss string `json:"iss"`
+}
+
+// parseUnverifiedJWT decodes tokenString's header and payload segments
+// without checking its signature.
+func parseUnverifiedJWT(tokenString string) (jwtHeader, jwtPayload, error) {
+	parts := strings.Split(tokenString, ".")
+	if len(parts) != 3 {
+		return jwtHeader{}, jwtPayload{}, fmt.Errorf("jwks: malformed JWT: expected 3 segments, got %d", len(parts))
+	}
+
+	headerBytes, err := decodeSegment(parts[0])
+	if err != nil {
+		return jwtHeader{}, jwtPayload{}, fmt.Errorf("jwks: decoding JWT header: %w", err)
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return jwtHeader{}, jwtPayload{}, fmt.Errorf("jwks: parsing JWT header: %w", err)
+	}
+
+	payloadBytes, err := decodeSegment(parts[1])
+	if err != nil {
+		return jwtHeader{}, jwtPayload{}, fmt.Errorf("jwks: decoding JWT payload: %w", err)
+	}
+	var payload jwtPayload
+	if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+		return jwtHeader{}, jwtPayload{}, fmt.Errorf("jwks: parsing JWT payload: %w", err)
+	}
+
+	return header, payload, nil
+}
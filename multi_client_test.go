@@ -0,0 +1,194 @@
+package jwks
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func jwksHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, `{"keys":[{"alg":"RS256","kty":"RSA","use":"sig","n":"VKOoRQ","e":"AQAB","kid":"GREY2MQ"}]}`)
+}
+
+func newTestIssuerServer(t *testing.T) (issuer string, jwksServer *httptest.Server, discoveryServer *httptest.Server) {
+	jwksServer = httptest.NewServer(http.HandlerFunc(jwksHandler))
+
+	discoveryServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"jwks_uri":%q}`, jwksServer.URL)
+	}))
+
+	return discoveryServer.URL, jwksServer, discoveryServer
+}
+
+func encodeJWTSegment(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	assert(t, err == nil)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func fakeJWT(t *testing.T, header, payload interface{}) string {
+	return encodeJWTSegment(t, header) + "." + encodeJWTSegment(t, payload) + ".sig"
+}
+
+func TestNewMultiClientDiscoversAndRoutes(t *testing.T) {
+	issuer, jwksServer, discoveryServer := newTestIssuerServer(t)
+	defer jwksServer.Close()
+	defer discoveryServer.Close()
+
+	mc := NewMultiClient([]string{issuer}, nil)
+	key, err := mc.GetSigningKey(context.Background(), issuer, "GREY2MQ")
+	assert(t, err == nil)
+	assert(t, key != nil)
+	assert(t, key.Kid == "GREY2MQ")
+}
+
+func TestMultiClientRejectsUnlistedIssuer(t *testing.T) {
+	issuer, jwksServer, discoveryServer := newTestIssuerServer(t)
+	defer jwksServer.Close()
+	defer discoveryServer.Close()
+
+	mc := NewMultiClient([]string{issuer}, nil)
+	_, err := mc.GetSigningKey(context.Background(), "https://evil.example.com", "GREY2MQ")
+	assert(t, err != nil)
+}
+
+func TestMultiClientCachesClientPerIssuer(t *testing.T) {
+	issuer, jwksServer, discoveryServer := newTestIssuerServer(t)
+	defer jwksServer.Close()
+
+	mc := NewMultiClient([]string{issuer}, nil)
+	_, err := mc.GetSigningKey(context.Background(), issuer, "GREY2MQ")
+	assert(t, err == nil)
+
+	// Even with the discovery document now gone, the cached Client for this
+	// issuer should still be used rather than re-discovering.
+	discoveryServer.Close()
+
+	_, err = mc.GetSigningKey(context.Background(), issuer, "GREY2MQ")
+	assert(t, err == nil)
+}
+
+func TestNewMultiClientWithResolver(t *testing.T) {
+	jwksServer := httptest.NewServer(http.HandlerFunc(jwksHandler))
+	defer jwksServer.Close()
+
+	resolver := func(ctx context.Context, iss string) (string, error) {
+		return jwksServer.URL, nil
+	}
+
+	mc := NewMultiClientWithResolver(resolver, nil)
+	key, err := mc.GetSigningKey(context.Background(), "https://unknown.example.com", "GREY2MQ")
+	assert(t, err == nil)
+	assert(t, key != nil)
+}
+
+func TestMultiClientEvictsLeastRecentlyUsedIssuer(t *testing.T) {
+	jwksServer := httptest.NewServer(http.HandlerFunc(jwksHandler))
+	defer jwksServer.Close()
+
+	resolver := func(ctx context.Context, iss string) (string, error) {
+		return jwksServer.URL, nil
+	}
+
+	mc := NewMultiClientWithResolver(resolver, nil)
+	mc.maxIssuers = 2
+
+	_, err := mc.GetSigningKey(context.Background(), "https://a.example.com", "GREY2MQ")
+	assert(t, err == nil)
+	_, err = mc.GetSigningKey(context.Background(), "https://b.example.com", "GREY2MQ")
+	assert(t, err == nil)
+	_, err = mc.GetSigningKey(context.Background(), "https://c.example.com", "GREY2MQ")
+	assert(t, err == nil)
+
+	assert(t, len(mc.clients) == 2)
+	_, stillCached := mc.clients["https://a.example.com"]
+	assert(t, !stillCached)
+	_, stillCached = mc.clients["https://c.example.com"]
+	assert(t, stillCached)
+}
+
+func TestMultiClientVerifyJWT(t *testing.T) {
+	issuer, jwksServer, discoveryServer := newTestIssuerServer(t)
+	defer jwksServer.Close()
+	defer discoveryServer.Close()
+
+	mc := NewMultiClient([]string{issuer}, nil)
+	token := fakeJWT(t,
+		map[string]string{"alg": "RS256", "kid": "GREY2MQ"},
+		map[string]string{"iss": issuer, "sub": "user-1"},
+	)
+
+	publicKey, err := mc.VerifyJWT(context.Background(), token)
+	assert(t, err == nil)
+	assert(t, publicKey != nil)
+}
+
+func TestMultiClientVerifyJWTMissingKid(t *testing.T) {
+	issuer, jwksServer, discoveryServer := newTestIssuerServer(t)
+	defer jwksServer.Close()
+	defer discoveryServer.Close()
+
+	mc := NewMultiClient([]string{issuer}, nil)
+	token := fakeJWT(t, map[string]string{"alg": "RS256"}, map[string]string{"iss": issuer})
+
+	_, err := mc.VerifyJWT(context.Background(), token)
+	assert(t, err != nil)
+}
+
+func TestMultiClientVerifyJWTMalformed(t *testing.T) {
+	mc := NewMultiClient(nil, nil)
+	_, err := mc.VerifyJWT(context.Background(), "not-a-jwt")
+	assert(t, err != nil)
+}
+
+func TestMultiClientNamespacesFileCachePerIssuer(t *testing.T) {
+	issuerA, jwksServerA, discoveryServerA := newTestIssuerServer(t)
+	defer jwksServerA.Close()
+	defer discoveryServerA.Close()
+	issuerB, jwksServerB, discoveryServerB := newTestIssuerServer(t)
+	defer jwksServerB.Close()
+	defer discoveryServerB.Close()
+
+	cache := NewFileCache(filepath.Join(t.TempDir(), "jwks-cache.json"))
+	mc := NewMultiClient([]string{issuerA, issuerB}, NewConfig().WithCache(cache))
+
+	_, err := mc.GetSigningKey(context.Background(), issuerA, "GREY2MQ")
+	assert(t, err == nil)
+	_, err = mc.GetSigningKey(context.Background(), issuerB, "GREY2MQ")
+	assert(t, err == nil)
+
+	clientA := mc.clients[issuerA]
+	clientB := mc.clients[issuerB]
+	assert(t, clientA.config.cache != nil)
+	assert(t, clientB.config.cache != nil)
+	assert(t, clientA.config.cache.(*FileCache).path != clientB.config.cache.(*FileCache).path)
+	assert(t, clientA.config.cache.(*FileCache).path != cache.path)
+}
+
+type nonNamespaceableCache struct{}
+
+func (nonNamespaceableCache) Get(ctx context.Context) (*Keys, time.Time, error) {
+	return nil, time.Time{}, fmt.Errorf("not implemented")
+}
+
+func (nonNamespaceableCache) Put(ctx context.Context, keys *Keys, expiration time.Time) error {
+	return nil
+}
+
+func TestMultiClientDisablesNonNamespaceableCache(t *testing.T) {
+	issuer, jwksServer, discoveryServer := newTestIssuerServer(t)
+	defer jwksServer.Close()
+	defer discoveryServer.Close()
+
+	mc := NewMultiClient([]string{issuer}, NewConfig().WithCache(nonNamespaceableCache{}))
+	_, err := mc.GetSigningKey(context.Background(), issuer, "GREY2MQ")
+	assert(t, err == nil)
+
+	assert(t, mc.clients[issuer].config.cache == nil)
+}
@@ -0,0 +1,129 @@
+package jwks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Cache is a pluggable persistent store for a Client's key set, letting it
+// survive a process restart without waiting on a network fetch before it
+// can verify its first token. Implementations might write to disk, Redis,
+// or any other store with get/put semantics.
+type Cache interface {
+	// Get returns the persisted key set and the hard expiration it was
+	// stored with. It returns an error if nothing has been persisted yet
+	// or the store is unreachable.
+	Get(ctx context.Context) (*Keys, time.Time, error)
+
+	// Put persists the key set and the hard expiration it was fetched
+	// with.
+	Put(ctx context.Context, keys *Keys, expiration time.Time) error
+}
+
+// NamespacedCache is implemented by Cache implementations that can be
+// sharded per namespace, such as a FileCache deriving one file per
+// namespace. MultiClient requires its configured Cache to implement this
+// so each issuer's sub-Client gets its own storage instead of every issuer
+// reading and overwriting the same persisted key set.
+type NamespacedCache interface {
+	Cache
+
+	// ForNamespace returns a Cache scoped to ns.
+	ForNamespace(ns string) Cache
+}
+
+// withinCacheGrace reports whether a Cache is configured and the client's
+// last known key set is still within its grace period, meaning it may be
+// served even though the JWKS endpoint is currently unreachable.
+func (c *Client) withinCacheGrace() bool {
+	if c.config.cache == nil {
+		return false
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.keys == nil {
+		return false
+	}
+	return time.Now().Before(c.hardExpiration.Add(c.config.cacheGracePeriod * time.Second))
+}
+
+// FileCache is a Cache that atomically writes the key set to a JSON file
+// on disk, in the spirit of the on-disk cache used by x/crypto/acme/autocert.
+type FileCache struct {
+	path string
+}
+
+// NewFileCache creates a FileCache that persists to the given file path.
+func NewFileCache(path string) *FileCache {
+	return &FileCache{path: path}
+}
+
+type fileCacheEntry struct {
+	Keys       *Keys     `json:"keys"`
+	Expiration time.Time `json:"expiration"`
+}
+
+// Get reads the persisted key set from disk.
+func (f *FileCache) Get(ctx context.Context) (*Keys, time.Time, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var entry fileCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, time.Time{}, err
+	}
+	return entry.Keys, entry.Expiration, nil
+}
+
+// Put atomically writes the key set to disk: the new contents are written
+// to a temporary file in the same directory, then renamed into place, so a
+// concurrent reader never observes a partial write.
+func (f *FileCache) Put(ctx context.Context, keys *Keys, expiration time.Time) error {
+	data, err := json.Marshal(fileCacheEntry{Keys: keys, Expiration: expiration})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), ".jwks-cache-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// ForNamespace returns a FileCache persisting to a path derived from f's
+// path and ns, so distinct namespaces (e.g. one per issuer in a
+// MultiClient) don't clobber each other's cache file.
+func (f *FileCache) ForNamespace(ns string) Cache {
+	return NewFileCache(namespacedPath(f.path, ns))
+}
+
+// namespacedPath inserts a short hash of ns before path's extension, e.g.
+// "/var/jwks-cache.json" + "https://issuer.example.com" ->
+// "/var/jwks-cache.605ec2f11533f3f2.json".
+func namespacedPath(path, ns string) string {
+	sum := sha256.Sum256([]byte(ns))
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	return fmt.Sprintf("%s.%x%s", base, sum[:8], ext)
+}
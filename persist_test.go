@@ -0,0 +1,79 @@
+package jwks
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileCacheRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks-cache.json")
+	cache := NewFileCache(path)
+
+	keys := &Keys{Keys: []Key{{Kid: "GREY2MQ", Kty: "RSA", Use: "sig"}}}
+	expiration := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	err := cache.Put(context.Background(), keys, expiration)
+	assert(t, err == nil)
+
+	gotKeys, gotExpiration, err := cache.Get(context.Background())
+	assert(t, err == nil)
+	assert(t, len(gotKeys.Keys) == 1)
+	assert(t, gotKeys.Keys[0].Kid == "GREY2MQ")
+	assert(t, gotExpiration.Equal(expiration))
+}
+
+func TestFileCacheGetMissingFile(t *testing.T) {
+	cache := NewFileCache(filepath.Join(t.TempDir(), "does-not-exist.json"))
+	_, _, err := cache.Get(context.Background())
+	assert(t, err != nil)
+}
+
+func TestNewClientLoadsFromCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks-cache.json")
+	cache := NewFileCache(path)
+	expiration := time.Now().Add(time.Hour)
+	err := cache.Put(context.Background(), &Keys{Keys: []Key{{Kid: "GREY2MQ", Use: "sig"}}}, expiration)
+	assert(t, err == nil)
+
+	client := NewClient("http://ilikepie.com", NewConfig().WithCache(cache))
+	assert(t, client.keys != nil)
+	assert(t, len(client.keys.Keys) == 1)
+
+	key, err := client.GetSigningKey("GREY2MQ")
+	assert(t, err == nil)
+	assert(t, key != nil)
+}
+
+func TestGetKeysServesStaleWithinGraceOnFetchError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks-cache.json")
+	cache := NewFileCache(path)
+	expiration := time.Now().Add(-time.Minute)
+	err := cache.Put(context.Background(), &Keys{Keys: []Key{{Kid: "GREY2MQ", Use: "sig"}}}, expiration)
+	assert(t, err == nil)
+
+	config := NewConfig().WithCache(cache).WithCacheGracePeriod(time.Duration(3600))
+	client := NewClient("http://ilikepie.com", config)
+	client.httpClient = &http.Client{Transport: &mockErrorTransport{}}
+
+	keys, err := client.GetKeys()
+	assert(t, err == nil)
+	assert(t, len(keys) == 1)
+}
+
+func TestGetKeysFailsOnceGraceExceeded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks-cache.json")
+	cache := NewFileCache(path)
+	expiration := time.Now().Add(-time.Hour)
+	err := cache.Put(context.Background(), &Keys{Keys: []Key{{Kid: "GREY2MQ", Use: "sig"}}}, expiration)
+	assert(t, err == nil)
+
+	config := NewConfig().WithCache(cache).WithCacheGracePeriod(time.Duration(1))
+	client := NewClient("http://ilikepie.com", config)
+	client.httpClient = &http.Client{Transport: &mockErrorTransport{}}
+
+	_, err = client.GetKeys()
+	assert(t, err != nil)
+}
@@ -0,0 +1,96 @@
+package jwks
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// setExpiration updates the soft and hard cache expirations based on the
+// response's cache freshness signal. Must be called with the write lock
+// held.
+func (c *Client) setExpiration(resp *http.Response) {
+	now := time.Now()
+	hardTTL := c.cacheTTL(resp)
+	softTTL := c.config.softTTL * time.Second
+	if softTTL > hardTTL {
+		softTTL = hardTTL
+	}
+	c.hardExpiration = now.Add(hardTTL)
+	c.softExpiration = now.Add(softTTL)
+}
+
+// shouldRefreshForUnknownKid reports whether an out-of-band refresh should
+// be triggered for a kid that wasn't found in the cached key set, rate
+// limiting to at most once per minRefreshInterval so a flood of requests
+// for an invalid kid can't be used to hammer the JWKS endpoint.
+func (c *Client) shouldRefreshForUnknownKid() bool {
+	c.unknownKidMu.Lock()
+	defer c.unknownKidMu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastUnknownKidRefresh) < c.config.minRefreshInterval*time.Second {
+		return false
+	}
+	c.lastUnknownKidRefresh = now
+	return true
+}
+
+// refreshGroup coalesces concurrent key set refreshes into a single fetch,
+// similar in spirit to golang.org/x/sync/singleflight, but specialized for
+// a single in-flight call since a Client only ever refreshes one key set.
+type refreshGroup struct {
+	mu   sync.Mutex
+	call *refreshCall
+}
+
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
+// do runs fn and returns its error, joining an already in-flight call
+// (triggered by do or spawn) instead of starting a redundant one.
+func (g *refreshGroup) do(fn func() error) error {
+	g.mu.Lock()
+	if c := g.call; c != nil {
+		g.mu.Unlock()
+		<-c.done
+		return c.err
+	}
+	c := &refreshCall{done: make(chan struct{})}
+	g.call = c
+	g.mu.Unlock()
+
+	c.err = fn()
+	close(c.done)
+
+	g.mu.Lock()
+	g.call = nil
+	g.mu.Unlock()
+
+	return c.err
+}
+
+// spawn starts fn in the background unless a refresh is already in flight,
+// in which case it's a no-op since that refresh will produce an up-to-date
+// result anyway. Callers that need the result should use do instead.
+func (g *refreshGroup) spawn(fn func() error) {
+	g.mu.Lock()
+	if g.call != nil {
+		g.mu.Unlock()
+		return
+	}
+	c := &refreshCall{done: make(chan struct{})}
+	g.call = c
+	g.mu.Unlock()
+
+	go func() {
+		c.err = fn()
+		close(c.done)
+
+		g.mu.Lock()
+		g.call = nil
+		g.mu.Unlock()
+	}()
+}
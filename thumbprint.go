@@ -0,0 +1,95 @@
+package jwks
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"fmt"
+)
+
+// Thumbprint computes the RFC 7638 JSON Web Key thumbprint: hash is applied
+// to the canonical JSON representation of the key's required members, in
+// the fixed lexicographic order the RFC mandates and with no whitespace
+// ({"e","kty","n"} for RSA, {"crv","kty","x","y"} for EC, {"crv","kty","x"}
+// for OKP, {"k","kty"} for oct).
+func (k *Key) Thumbprint(hash crypto.Hash) ([]byte, error) {
+	var canonical string
+
+	switch k.Kty {
+	case "RSA":
+		if k.E == "" || k.N == "" {
+			return nil, fmt.Errorf("jwks: RSA key %q missing n or e", k.Kid)
+		}
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, k.E, k.N)
+	case "EC":
+		if k.Crv == "" || k.X == "" || k.Y == "" {
+			return nil, fmt.Errorf("jwks: EC key %q missing crv, x, or y", k.Kid)
+		}
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, k.Crv, k.X, k.Y)
+	case "OKP":
+		if k.Crv == "" || k.X == "" {
+			return nil, fmt.Errorf("jwks: OKP key %q missing crv or x", k.Kid)
+		}
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, k.Crv, k.X)
+	case "oct":
+		if k.K == "" {
+			return nil, fmt.Errorf("jwks: oct key %q missing k", k.Kid)
+		}
+		canonical = fmt.Sprintf(`{"k":%q,"kty":"oct"}`, k.K)
+	default:
+		return nil, fmt.Errorf("jwks: unsupported key type %q for kid %q", k.Kty, k.Kid)
+	}
+
+	if !hash.Available() {
+		return nil, fmt.Errorf("jwks: requested hash is not available")
+	}
+
+	h := hash.New()
+	h.Write([]byte(canonical))
+	return h.Sum(nil), nil
+}
+
+// GetKeyByThumbprint returns the signing key whose RFC 7638 thumbprint,
+// computed with the given hash, matches thumb, or nil if none matches.
+func (c *Client) GetKeyByThumbprint(ctx context.Context, hash crypto.Hash, thumb []byte) (*Key, error) {
+	keys, err := c.GetKeysContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if key.Use != "sig" {
+			continue
+		}
+		fingerprint, terr := key.Thumbprint(hash)
+		if terr != nil {
+			continue
+		}
+		if bytes.Equal(fingerprint, thumb) {
+			return &key, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetKeyByX5t returns the signing key whose "x5t" (SHA-1) or "x5t#S256"
+// (SHA-256) X.509 certificate thumbprint matches x5t, or nil if none
+// matches. x5t is compared as given, since issuers vary in whether they
+// populate "x5t" or "x5t#S256".
+func (c *Client) GetKeyByX5t(ctx context.Context, x5t string) (*Key, error) {
+	if x5t == "" {
+		return nil, nil
+	}
+
+	keys, err := c.GetKeysContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if key.Use == "sig" && ((key.X5t != "" && key.X5t == x5t) || (key.X5tS256 != "" && key.X5tS256 == x5t)) {
+			return &key, nil
+		}
+	}
+	return nil, nil
+}
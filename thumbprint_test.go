@@ -0,0 +1,77 @@
+package jwks
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestKeyThumbprintRSACanonicalForm(t *testing.T) {
+	key := &Key{Kty: "RSA", N: testRSAN, E: testRSAE}
+	thumb, err := key.Thumbprint(crypto.SHA256)
+	assert(t, err == nil)
+
+	want := sha256.Sum256([]byte(`{"e":"` + testRSAE + `","kty":"RSA","n":"` + testRSAN + `"}`))
+	assert(t, string(thumb) == string(want[:]))
+}
+
+func TestKeyThumbprintEC(t *testing.T) {
+	key := &Key{Kty: "EC", Crv: "P-256", X: testECX, Y: testECY}
+	thumb, err := key.Thumbprint(crypto.SHA256)
+	assert(t, err == nil)
+	assert(t, len(thumb) == crypto.SHA256.Size())
+}
+
+func TestKeyThumbprintOKP(t *testing.T) {
+	key := &Key{Kty: "OKP", Crv: "Ed25519", X: testOKPX}
+	thumb, err := key.Thumbprint(crypto.SHA256)
+	assert(t, err == nil)
+	assert(t, len(thumb) == crypto.SHA256.Size())
+}
+
+func TestKeyThumbprintOct(t *testing.T) {
+	key := &Key{Kty: "oct", K: "c2VjcmV0"}
+	thumb, err := key.Thumbprint(crypto.SHA256)
+	assert(t, err == nil)
+	assert(t, len(thumb) == crypto.SHA256.Size())
+}
+
+func TestKeyThumbprintMissingFields(t *testing.T) {
+	key := &Key{Kty: "RSA"}
+	_, err := key.Thumbprint(crypto.SHA256)
+	assert(t, err != nil)
+}
+
+func TestKeyThumbprintUnsupportedKty(t *testing.T) {
+	key := &Key{Kty: "unknown"}
+	_, err := key.Thumbprint(crypto.SHA256)
+	assert(t, err != nil)
+}
+
+func TestGetKeyByThumbprint(t *testing.T) {
+	client := setupMockedHTTPTest("success")
+	key := &Key{Kty: "RSA", N: "VKOoRQ", E: "AQAB"}
+	thumb, err := key.Thumbprint(crypto.SHA256)
+	assert(t, err == nil)
+
+	found, err := client.GetKeyByThumbprint(context.Background(), crypto.SHA256, thumb)
+	assert(t, err == nil)
+	assert(t, found != nil)
+	assert(t, found.Kid == "GREY2MQ")
+}
+
+func TestGetKeyByX5t(t *testing.T) {
+	client := setupMockedHTTPTest("success")
+	found, err := client.GetKeyByX5t(context.Background(), "GREY2MQ")
+	assert(t, err == nil)
+	assert(t, found != nil)
+	assert(t, found.Kid == "GREY2MQ")
+}
+
+func TestGetKeyByX5tNoMatch(t *testing.T) {
+	client := setupMockedHTTPTest("success")
+	found, err := client.GetKeyByX5t(context.Background(), "nope")
+	assert(t, err == nil)
+	assert(t, found == nil)
+}